@@ -4,15 +4,65 @@ import (
 	"bufio"
 	"count_mean/util"
 	"encoding/csv"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"math"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// idLabels 為 true 時結果檔的列名改用固定的英文代號，方便程式比對
+var idLabels bool
+
+var labelIDs = map[string]string{
+	"開始秒數":         "start_time",
+	"結束秒數":         "end_time",
+	"最大平均值":        "max_mean",
+	"啟跳下蹲階段 最大值":   "countermovement_max",
+	"啟跳上升階段 最大值":   "push_off_max",
+	"團身階段 最大值":     "tuck_max",
+	"下降階段 最大值":     "descent_max",
+	"啟跳下蹲階段 平均值":   "countermovement_mean",
+	"啟跳上升階段 平均值":   "push_off_mean",
+	"團身階段 平均值":     "tuck_mean",
+	"下降階段 平均值":     "descent_mean",
+	"整個階段最大值出現在_秒": "overall_max_time",
+	"接觸_":          "contact_",
+	"離地_":          "toe_off_",
+	"最大力量_":        "peak_force_",
+	"關節角度_":        "joint_angle_",
+	"列":            "row",
+	"欄":            "column",
+	"原檔":           "value_a",
+	"比較檔":          "value_b",
+	"差值":           "difference",
+}
+
+func label(s string) string {
+	if id, ok := labelIDs[s]; ok && idLabels {
+		return id
+	}
+	return s
+}
+
 func main() {
+	selftest := flag.Bool("selftest", false, "執行自我檢查後結束")
+	flag.BoolVar(&idLabels, "ids", false, "結果檔的列名使用固定英文代號")
+	flag.Parse()
+	if *selftest {
+		if err := runSelftest(); err != nil {
+			fmt.Println("selftest failed:", err)
+			os.Exit(1)
+		}
+		fmt.Println("selftest ok")
+		return
+	}
 	var file string
 	fmt.Print("請輸入載入檔名: ")
 	reader := bufio.NewReader(os.Stdin)
@@ -34,8 +84,13 @@ func main() {
 		panic(err)
 	}
 	var fn int
-	fmt.Print("1. 某幾筆數平均最大值\n2. 每一行同除一個值\n3. 分期處理\n選擇功能(輸入數字): ")
+	fmt.Print("1. 某幾筆數平均最大值\n2. 每一行同除一個值\n3. 分期處理\n4. 力板事件偵測\n5. 關節角度計算\n6. 左右對稱指數\n7. 分割試次\n8. 擷取時間區段\n9. 比較兩個結果檔\n選擇功能(輸入數字): ")
 	fmt.Scanln(&fn)
+	// 比較結果檔要看原始內容，不做補值與排序
+	if fn == 9 {
+		fn9(records)
+		return
+	}
 	switch fn {
 	case 1:
 		fn1(records)
@@ -43,76 +98,96 @@ func main() {
 		fn2(records)
 	case 3:
 		fn3(records)
+	case 4:
+		fn4(records)
+	case 5:
+		fn5(records)
+	case 6:
+		fn6(records)
+	case 7:
+		fn7(records)
+	case 8:
+		fn8(records)
 	}
 }
 
 func fn1(r [][]string) {
+	r, ok := prepare(r, allColumns(r), true)
+	if !ok {
+		return
+	}
 	l := len(r)
 	columnMax := len(r[0])
-	var n int
-	fmt.Print("多少資料的平均(輸入數字): ")
-	fmt.Scanln(&n)
+	fmt.Print("多少資料的平均(輸入數字，或加 ms 以毫秒計): ")
+	reader := bufio.NewReader(os.Stdin)
+	size, _ := reader.ReadString('\n')
+	size = strings.TrimSpace(size)
+	rate, _ := samplingRate(r)
+	if strings.HasSuffix(size, "ms") && rate <= 0 {
+		fmt.Println("無法偵測取樣頻率，請改輸入資料筆數QQ")
+		time.Sleep(5 * time.Second)
+		return
+	}
+	n := windowSize(size, rate)
 	if l-1 < n || n < 1 {
 		fmt.Println("輸入錯誤QQ")
 		time.Sleep(5 * time.Second)
+		return
+	}
+	if strings.HasSuffix(size, "ms") {
+		fmt.Printf("%s 約為 %d 筆資料\n", size, n)
 	}
 	result := make([][]string, 0, 4)
 	result = append(result, r[0])
 	count := make(map[int][]string)
 	for i := 1; i < columnMax; i++ {
-		maxMean := 0
-		from := 0
-		move := 10
-		for j := 1; j <= l-n; j++ {
-			numbers := make([]float64, 0, n)
-			for k := j; k < j+n; k++ {
-				numbers = append(numbers, util.Str2Number[float64, int](r[k][i], move))
-			}
-			m := int(util.ArrayMean(numbers))
-			if m > maxMean {
-				maxMean = m
-				from = j
-			}
-		}
-		count[i] = []string{r[from][0], r[from+n-1][0], fmt.Sprintf("%.10f", float64(maxMean)/math.Pow10(move))}
+		from, maxMean := maxMeanWindow(r, i, n)
+		count[i] = []string{r[from][0], r[from+n-1][0], fmt.Sprintf("%.10f", maxMean)}
 	}
 	for i := 0; i < 3; i++ {
 		row := make([]string, 0, l)
 		switch i {
 		case 0:
-			row = append(row, "開始秒數")
+			row = append(row, label("開始秒數"))
 		case 1:
-			row = append(row, "結束秒數")
+			row = append(row, label("結束秒數"))
 		case 2:
-			row = append(row, "最大平均值")
+			row = append(row, label("最大平均值"))
 		}
 		for j := 1; j < columnMax; j++ {
 			row = append(row, count[j][i])
 		}
 		result = append(result, row)
 	}
-	file, err := os.Create("fn1_result.csv")
-	defer func(file *os.File) {
-		e := file.Close()
-		if e != nil {
-
-		}
-	}(file)
-	if err != nil {
-		log.Fatalln("failed to open file", err)
+	if err := writeResult("fn1_result.csv", result); err != nil {
+		log.Fatalln(err)
 	}
+}
 
-	bom := []byte{0xEF, 0xBB, 0xBF}
-	file.Write(bom)
-	w := csv.NewWriter(file)
-	w.Comma = ','
-	err = w.WriteAll(result)
-	if err != nil {
-		log.Fatalln("failed to write result", err)
+// maxMeanWindow 找出第 column 欄連續 n 筆平均最大的區段，回傳起始列與平均值
+func maxMeanWindow(r [][]string, column int, n int) (int, float64) {
+	maxMean := 0
+	from := 0
+	move := 10
+	for j := 1; j <= len(r)-n; j++ {
+		numbers := make([]float64, 0, n)
+		for k := j; k < j+n; k++ {
+			numbers = append(numbers, util.Str2Number[float64, int](r[k][column], move))
+		}
+		m := int(util.ArrayMean(numbers))
+		if m > maxMean {
+			maxMean = m
+			from = j
+		}
 	}
+	return from, float64(maxMean) / math.Pow10(move)
 }
 
 func fn2(r [][]string) {
+	r, ok := prepare(r, allColumns(r), false)
+	if !ok {
+		return
+	}
 	columnMax := len(r[0])
 	move := 10
 
@@ -147,27 +222,16 @@ func fn2(r [][]string) {
 		}
 		result = append(result, row)
 	}
-	resultFile, err := os.Create("fn2_result.csv")
-	defer func(file *os.File) {
-		e := file.Close()
-		if e != nil {
-
-		}
-	}(resultFile)
-	if err != nil {
-		log.Fatalln("failed to open file", err)
-	}
-
-	bom := []byte{0xEF, 0xBB, 0xBF}
-	resultFile.Write(bom)
-	w := csv.NewWriter(resultFile)
-	err = w.WriteAll(result)
-	if err != nil {
-		log.Fatalln("failed to write result", err)
+	if err := writeResult("fn2_result.csv", result); err != nil {
+		log.Fatalln(err)
 	}
 }
 
 func fn3(r [][]string) {
+	r, ok := prepare(r, allColumns(r), true)
+	if !ok {
+		return
+	}
 	l := len(r)
 	columnMax := len(r[0])
 	move := 10
@@ -194,6 +258,15 @@ func fn3(r [][]string) {
 	if err != nil {
 		panic(err)
 	}
+	if !checkPhasePoints(oValue, r) {
+		time.Sleep(5 * time.Second)
+		return
+	}
+	if k := emptyPhase(oValue, r); k != -1 {
+		fmt.Printf("%s 到 %s 之間沒有資料QQ\n", oValue[k+1][0], oValue[k+2][0])
+		time.Sleep(5 * time.Second)
+		return
+	}
 	operate := make([]string, 0, 5)
 	for i := 1; i < len(oValue); i++ {
 		operate = append(operate, oValue[i][1])
@@ -233,55 +306,55 @@ func fn3(r [][]string) {
 		row := make([]string, 0, columnMax)
 		switch i {
 		case 0:
-			row = append(row, "啟跳下蹲階段 最大值")
+			row = append(row, label("啟跳下蹲階段 最大值"))
 			for j := 1; j < columnMax; j++ {
 				m, _ := util.ArrayMax[float64](count1[j])
 				row = append(row, fmt.Sprintf("%.10f", m/math.Pow10(10)))
 			}
 		case 1:
-			row = append(row, "啟跳上升階段 最大值")
+			row = append(row, label("啟跳上升階段 最大值"))
 			for j := 1; j < columnMax; j++ {
 				m, _ := util.ArrayMax[float64](count2[j])
 				row = append(row, fmt.Sprintf("%.10f", m/math.Pow10(10)))
 			}
 		case 2:
-			row = append(row, "團身階段 最大值")
+			row = append(row, label("團身階段 最大值"))
 			for j := 1; j < columnMax; j++ {
 				m, _ := util.ArrayMax[float64](count3[j])
 				row = append(row, fmt.Sprintf("%.10f", m/math.Pow10(10)))
 			}
 		case 3:
-			row = append(row, "下降階段 最大值")
+			row = append(row, label("下降階段 最大值"))
 			for j := 1; j < columnMax; j++ {
 				m, _ := util.ArrayMax[float64](count4[j])
 				row = append(row, fmt.Sprintf("%.10f", m/math.Pow10(10)))
 			}
 		case 4:
-			row = append(row, "啟跳下蹲階段 平均值")
+			row = append(row, label("啟跳下蹲階段 平均值"))
 			for j := 1; j < columnMax; j++ {
 				mean := util.ArrayMean[float64](count1[j])
 				row = append(row, fmt.Sprintf("%.10f", mean/math.Pow10(10)))
 			}
 		case 5:
-			row = append(row, "啟跳上升階段 平均值")
+			row = append(row, label("啟跳上升階段 平均值"))
 			for j := 1; j < columnMax; j++ {
 				mean := util.ArrayMean[float64](count2[j])
 				row = append(row, fmt.Sprintf("%.10f", mean/math.Pow10(10)))
 			}
 		case 6:
-			row = append(row, "團身階段 平均值")
+			row = append(row, label("團身階段 平均值"))
 			for j := 1; j < columnMax; j++ {
 				mean := util.ArrayMean[float64](count3[j])
 				row = append(row, fmt.Sprintf("%.10f", mean/math.Pow10(10)))
 			}
 		case 7:
-			row = append(row, "下降階段 平均值")
+			row = append(row, label("下降階段 平均值"))
 			for j := 1; j < columnMax; j++ {
 				mean := util.ArrayMean[float64](count4[j])
 				row = append(row, fmt.Sprintf("%.10f", mean/math.Pow10(10)))
 			}
 		case 8:
-			row = append(row, "整個階段最大值出現在_秒")
+			row = append(row, label("整個階段最大值出現在_秒"))
 			for j := 1; j < columnMax; j++ {
 				_, index := util.ArrayMax[float64](countAllMax[j])
 				row = append(row, fmt.Sprintf("%.2f", util.Str2Number[float64](r[index+1][0], 0)))
@@ -290,22 +363,697 @@ func fn3(r [][]string) {
 		result = append(result, row)
 	}
 
-	resultFile, err := os.Create("fn3_result.csv")
-	defer func(file *os.File) {
-		e := file.Close()
+	if err := writeResult("fn3_result.csv", result); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func fn4(r [][]string) {
+	l := len(r)
+	columnMax := len(r[0])
+	var column int
+	var threshold float64
+	fmt.Print("垂直力在第幾欄(輸入數字): ")
+	fmt.Scanln(&column)
+	if column < 1 || column >= columnMax {
+		fmt.Println("輸入錯誤QQ")
+		time.Sleep(5 * time.Second)
+		return
+	}
+	fmt.Print("接觸閾值(輸入數字): ")
+	fmt.Scanln(&threshold)
+	r, ok := prepare(r, []int{column}, true)
+	if !ok {
+		return
+	}
+	l = len(r)
+	force := make([]float64, 0, l)
+	for i := 1; i < l; i++ {
+		force = append(force, util.Str2Number[float64, int](r[i][column], 0))
+	}
+	contacts := util.SplitByThreshold[float64](force, threshold)
+	if len(contacts) == 0 {
+		fmt.Println("找不到超過閾值的資料QQ")
+		time.Sleep(5 * time.Second)
+		return
+	}
+	_, peak := util.ArrayMax[float64](force)
+	name := r[0][column]
+	result := make([][]string, 0, 2*len(contacts)+2)
+	result = append(result, []string{"item", r[0][0]})
+	// 一開始就站在力板上或結束時還在力板上，那一端不是真正的接觸或離地
+	for k, contact := range contacts {
+		n := fmt.Sprintf("%s_%d", name, k+1)
+		if contact[0] > 0 {
+			result = append(result, []string{label("接觸_") + n, r[contact[0]+1][0]})
+		}
+		if contact[1] < len(force) {
+			result = append(result, []string{label("離地_") + n, r[contact[1]+1][0]})
+		}
+	}
+	result = append(result, []string{label("最大力量_") + name, r[peak+1][0]})
+	if err := writeResult("fn4_result.csv", result); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func fn5(r [][]string) {
+	l := len(r)
+	columnMax := len(r[0])
+	labels := []string{"近端", "關節", "遠端"}
+	columns := make([]int, 6)
+	for i, name := range labels {
+		fmt.Printf("%s標記的 X Y 在第幾欄(輸入兩個數字): ", name)
+		fmt.Scanln(&columns[2*i], &columns[2*i+1])
+	}
+	for _, c := range columns {
+		if c < 1 || c >= columnMax {
+			fmt.Println("輸入錯誤QQ")
+			time.Sleep(5 * time.Second)
+			return
+		}
+	}
+	r, ok := prepare(r, columns, false)
+	if !ok {
+		return
+	}
+	l = len(r)
+	result := make([][]string, 0, l)
+	result = append(result, []string{r[0][0], label("關節角度_") + r[0][columns[2]]})
+	for i := 1; i < l; i++ {
+		p := make([]float64, 6)
+		for j, c := range columns {
+			p[j] = util.Str2Number[float64, int](r[i][c], 0)
+		}
+		angle := util.JointAngle2D(p[0], p[1], p[2], p[3], p[4], p[5])
+		result = append(result, []string{r[i][0], fmt.Sprintf("%.10f", angle)})
+	}
+	if err := writeResult("fn5_result.csv", result); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func fn6(r [][]string) {
+	columnMax := len(r[0])
+	fmt.Print("左右配對欄位(例 1-2,3-4): ")
+	reader := bufio.NewReader(os.Stdin)
+	s, _ := reader.ReadString('\n')
+	s = strings.TrimSpace(s)
+	pairs := make([][2]int, 0, columnMax/2)
+	columns := make([]int, 0, columnMax)
+	for _, p := range strings.Split(s, ",") {
+		var left, right int
+		_, err := fmt.Sscanf(strings.TrimSpace(p), "%d-%d", &left, &right)
+		if err != nil || left < 1 || right < 1 || left >= columnMax || right >= columnMax {
+			fmt.Println("輸入錯誤QQ")
+			time.Sleep(5 * time.Second)
+			return
+		}
+		pairs = append(pairs, [2]int{left, right})
+		columns = append(columns, left, right)
+	}
+	r, ok := prepare(r, columns, false)
+	if !ok {
+		return
+	}
+	result := make([][]string, 0, len(r))
+	header := []string{r[0][0]}
+	for _, p := range pairs {
+		header = append(header, r[0][p[0]]+"/"+r[0][p[1]])
+	}
+	result = append(result, header)
+	for i := 1; i < len(r); i++ {
+		row := make([]string, 0, len(pairs)+1)
+		row = append(row, r[i][0])
+		for _, p := range pairs {
+			si := util.SymmetryIndex[float64](util.Str2Number[float64, int](r[i][p[0]], 0), util.Str2Number[float64, int](r[i][p[1]], 0))
+			row = append(row, fmt.Sprintf("%.10f", si))
+		}
+		result = append(result, row)
+	}
+	if err := writeResult("fn6_result.csv", result); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func fn7(r [][]string) {
+	columnMax := len(r[0])
+	var mode int
+	fmt.Print("1. 依時間間隔\n2. 依觸發脈衝(每次觸發到下一次觸發)\n3. 依閘門訊號(訊號持續高於閾值的區段)\n選擇分割方式(輸入數字): ")
+	fmt.Scanln(&mode)
+	var gap, threshold float64
+	var column int
+	var columns []int
+	switch mode {
+	case 1:
+		fmt.Print("間隔超過幾秒就分割(輸入數字): ")
+		fmt.Scanln(&gap)
+		if gap <= 0 {
+			fmt.Println("輸入錯誤QQ")
+			time.Sleep(5 * time.Second)
+			return
+		}
+	case 2, 3:
+		fmt.Print("觸發訊號在第幾欄(輸入數字): ")
+		fmt.Scanln(&column)
+		if column < 1 || column >= columnMax {
+			fmt.Println("輸入錯誤QQ")
+			time.Sleep(5 * time.Second)
+			return
+		}
+		fmt.Print("觸發閾值(輸入數字): ")
+		fmt.Scanln(&threshold)
+		columns = []int{column}
+	default:
+		fmt.Println("輸入錯誤QQ")
+		time.Sleep(5 * time.Second)
+		return
+	}
+	r, ok := prepare(r, columns, true)
+	if !ok {
+		return
+	}
+	l := len(r)
+	var segments [][2]int
+	switch mode {
+	case 1:
+		times := make([]float64, 0, l)
+		for i := 1; i < l; i++ {
+			times = append(times, util.Str2Number[float64, int](r[i][0], 0))
+		}
+		segments = util.SplitByGap[float64](times, gap)
+	case 2, 3:
+		trigger := make([]float64, 0, l)
+		for i := 1; i < l; i++ {
+			trigger = append(trigger, util.Str2Number[float64, int](r[i][column], 0))
+		}
+		if mode == 2 {
+			segments = util.SplitByOnset[float64](trigger, threshold)
+		} else {
+			segments = util.SplitByThreshold[float64](trigger, threshold)
+		}
+	}
+	for k, segment := range segments {
+		result := make([][]string, 0, segment[1]-segment[0]+1)
+		result = append(result, r[0])
+		result = append(result, r[segment[0]+1:segment[1]+1]...)
+		if err := writeResult(fmt.Sprintf("fn7_result_%d.csv", k+1), result); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	fmt.Printf("共分割成 %d 個試次\n", len(segments))
+}
+
+func fn8(r [][]string) {
+	columnMax := len(r[0])
+	var start, end float64
+	fmt.Print("開始與結束秒數(輸入兩個數字): ")
+	fmt.Scanln(&start, &end)
+	if end < start {
+		fmt.Println("輸入錯誤QQ")
+		time.Sleep(5 * time.Second)
+		return
+	}
+	fmt.Print("要輸出的欄位(例 1,3,5，直接 Enter 輸出全部): ")
+	reader := bufio.NewReader(os.Stdin)
+	s, _ := reader.ReadString('\n')
+	s = strings.TrimSpace(s)
+	columns := []int{0}
+	if s == "" {
+		for j := 1; j < columnMax; j++ {
+			columns = append(columns, j)
+		}
+	} else {
+		for _, c := range strings.Split(s, ",") {
+			j, err := strconv.Atoi(strings.TrimSpace(c))
+			if err != nil || j < 1 || j >= columnMax {
+				fmt.Println("輸入錯誤QQ")
+				time.Sleep(5 * time.Second)
+				return
+			}
+			columns = append(columns, j)
+		}
+	}
+	r, ok := prepare(r, columns[1:], false)
+	if !ok {
+		return
+	}
+	result := make([][]string, 0, len(r))
+	for i := 0; i < len(r); i++ {
+		if i > 0 {
+			t := util.Str2Number[float64, int](r[i][0], 0)
+			if t < start || t > end {
+				continue
+			}
+		}
+		row := make([]string, 0, len(columns))
+		for _, j := range columns {
+			row = append(row, r[i][j])
+		}
+		result = append(result, row)
+	}
+	if err := writeResult("fn8_result.csv", result); err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Printf("共擷取 %d 筆資料\n", len(result)-1)
+}
+
+func fn9(r [][]string) {
+	var file string
+	fmt.Print("請輸入要比較的csv檔名: ")
+	reader := bufio.NewReader(os.Stdin)
+	file, _ = reader.ReadString('\n')
+	file = strings.TrimSpace(file)
+	f, err := os.Open(file + ".csv")
+	defer func(f *os.File) {
+		e := f.Close()
 		if e != nil {
 
 		}
-	}(resultFile)
+	}(f)
+	if err != nil {
+		panic(err)
+	}
+	o := csv.NewReader(f)
+	oValue, err := o.ReadAll()
+	if err != nil {
+		panic(err)
+	}
+	var tolerance float64
+	fmt.Print("容許誤差(輸入數字): ")
+	fmt.Scanln(&tolerance)
+	if tolerance < 0 {
+		fmt.Println("輸入錯誤QQ")
+		time.Sleep(5 * time.Second)
+		return
+	}
+	if len(r) != len(oValue) || len(r[0]) != len(oValue[0]) {
+		fmt.Printf("兩個檔案大小不同: %d x %d 與 %d x %d\n", len(r), len(r[0]), len(oValue), len(oValue[0]))
+	}
+	// 只存在於其中一個檔案的格子視為不同，另一邊留白
+	cell := func(rows [][]string, i, j int) (string, bool) {
+		if i >= len(rows) || j >= len(rows[i]) {
+			return "", false
+		}
+		return strings.TrimPrefix(rows[i][j], "\uFEFF"), true
+	}
+	rows := max(len(r), len(oValue))
+	result := make([][]string, 0, rows)
+	result = append(result, []string{label("列"), label("欄"), label("原檔"), label("比較檔"), label("差值")})
+	for i := 0; i < rows; i++ {
+		columns := 0
+		if i < len(r) {
+			columns = len(r[i])
+		}
+		if i < len(oValue) {
+			columns = max(columns, len(oValue[i]))
+		}
+		for j := 0; j < columns; j++ {
+			a, okA := cell(r, i, j)
+			b, okB := cell(oValue, i, j)
+			rowName, ok := cell(r, i, 0)
+			if !ok {
+				rowName, _ = cell(oValue, i, 0)
+			}
+			columnName, ok := cell(r, 0, j)
+			if !ok {
+				columnName, _ = cell(oValue, 0, j)
+			}
+			if okA != okB {
+				result = append(result, []string{rowName, columnName, a, b, ""})
+				continue
+			}
+			x, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+			y, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+			switch {
+			case errA == nil && errB == nil:
+				if math.Abs(x-y) > tolerance || math.IsNaN(x) != math.IsNaN(y) {
+					result = append(result, []string{rowName, columnName, a, b, fmt.Sprintf("%.10f", x-y)})
+				}
+			case a != b:
+				result = append(result, []string{rowName, columnName, a, b, ""})
+			}
+		}
+	}
+	if err := writeResult("fn9_result.csv", result); err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Printf("共 %d 格不同\n", len(result)-1)
+}
+
+// checkPhasePoints 檢查分期檔至少有 5 個時間點與兩個欄位、依序排列且落在資料時間範圍內
+func checkPhasePoints(o [][]string, r [][]string) bool {
+	if len(r) < 2 {
+		fmt.Println("資料檔沒有資料QQ")
+		return false
+	}
+	if len(o) < 6 {
+		fmt.Println("分期檔至少要有 5 個時間點QQ")
+		return false
+	}
+	if len(o[0]) < 2 {
+		fmt.Println("分期檔需要兩欄: 項目名稱與時間QQ")
+		return false
+	}
+	points := make([]float64, 0, len(o)-1)
+	for i := 1; i < len(o); i++ {
+		points = append(points, util.Str2Number[float64, int](o[i][1], 0))
+	}
+	if i := util.UnorderedIndex[float64](points); i != -1 {
+		fmt.Printf("分期時間點順序錯誤: %s(%s) 早於 %s(%s)QQ\n", o[i+1][0], o[i+1][1], o[i][0], o[i][1])
+		return false
+	}
+	first := util.Str2Number[float64, int](r[1][0], 0)
+	last := util.Str2Number[float64, int](r[len(r)-1][0], 0)
+	if points[0] < first || points[len(points)-1] > last {
+		fmt.Printf("分期時間點超出資料範圍 %s ~ %s 秒QQ\n", r[1][0], r[len(r)-1][0])
+		return false
+	}
+	return true
+}
+
+// emptyPhase 回傳第一個沒有資料列的階段，四個階段都有資料時回傳 -1
+func emptyPhase(o [][]string, r [][]string) int {
+	move := 10
+	for k := 0; k < 4; k++ {
+		from := util.Str2Number[float64, int](o[k+1][1], move)
+		to := util.Str2Number[float64, int](o[k+2][1], move)
+		found := false
+		for i := 1; i < len(r); i++ {
+			t := util.Str2Number[float64, int](r[i][0], move)
+			if t > from && t < to {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return k
+		}
+	}
+	return -1
+}
+
+// prepare 檢查時間欄與 columns 用到的資料欄，依使用者選擇補值、排序
+// needOrder 為 true 時時間欄必須依序排列，回傳 false 表示不能繼續分析
+func prepare(r [][]string, columns []int, needOrder bool) ([][]string, bool) {
+	strategy := 0
+	if missing := countMissing(r, columns); missing > 0 {
+		fmt.Printf("警告: 有 %d 格空白或非數字\n", missing)
+		fmt.Print("1. 線性內插\n2. 沿用前值\n3. 刪除該列\n4. 中止(嚴格模式)\n選擇處理方式(輸入數字): ")
+		fmt.Scanln(&strategy)
+		if strategy < 1 || strategy > 4 {
+			fmt.Println("輸入錯誤QQ")
+			time.Sleep(5 * time.Second)
+			return r, false
+		}
+		if strategy == 4 {
+			i, j := firstMissing(r, columns)
+			fmt.Printf("第 %d 行第 %d 欄(%s)無法解析: %q\n", i+1, j+1, r[0][j], r[i][j])
+			time.Sleep(5 * time.Second)
+			os.Exit(1)
+		}
+		r = dropMissingTime(r)
+	}
+	ordered := true
+	if times, ok := timeColumn(r); ok {
+		if backwards, duplicated := util.TimeDisorder[float64](times); backwards > 0 || duplicated > 0 {
+			var repair int
+			fmt.Printf("警告: 時間欄有 %d 處倒退、%d 個重複\n", backwards, duplicated)
+			fmt.Print("1. 排序並刪除重複時間\n2. 不處理\n選擇處理方式(輸入數字): ")
+			fmt.Scanln(&repair)
+			if repair == 1 {
+				r = sortByTime(r, times)
+			} else {
+				ordered = false
+			}
+		}
+	}
+	if strategy != 0 {
+		r = fillMissing(r, columns, strategy)
+	}
+	rate, irregular := samplingRate(r)
+	if rate > 0 {
+		fmt.Printf("取樣頻率約 %.2f Hz\n", rate)
+	}
+	if irregular > 0 {
+		fmt.Printf("警告: 時間欄有 %d 個間隔不規則(重複、倒退或跳點)\n", irregular)
+	}
+	if !ordered && needOrder {
+		fmt.Println("時間欄未排序或有重複，無法進行此分析QQ")
+		time.Sleep(5 * time.Second)
+		return r, false
+	}
+	return r, true
+}
+
+// allColumns 回傳所有資料欄(第二欄起)的位置
+func allColumns(r [][]string) []int {
+	columns := make([]int, 0, len(r[0]))
+	for j := 1; j < len(r[0]); j++ {
+		columns = append(columns, j)
+	}
+	return columns
+}
+
+// countMissing 回傳空白或無法解析的時間與 columns 資料欄中空白或非數字的格數
+func countMissing(r [][]string, columns []int) int {
+	numeric := numericTime(r)
+	missing := 0
+	for i := 1; i < len(r); i++ {
+		if missingCell(r[i], 0, numeric) {
+			missing++
+		}
+		for _, j := range columns {
+			if missingCell(r[i], j, true) {
+				missing++
+			}
+		}
+	}
+	return missing
+}
+
+// missingCell 判斷格子是否空白或無法解析；第一欄是列名(numericTime 為 false)時只看是否空白
+func missingCell(row []string, j int, numericTime bool) bool {
+	if j == 0 && !numericTime {
+		return strings.TrimSpace(row[0]) == ""
+	}
+	_, err := strconv.ParseFloat(strings.TrimSpace(row[j]), 64)
+	return err != nil
+}
+
+// numericTime 判斷第一欄是否為時間：非空白的格子中大多數是數字
+func numericTime(r [][]string) bool {
+	numeric, text := 0, 0
+	for i := 1; i < len(r); i++ {
+		s := strings.TrimSpace(r[i][0])
+		if s == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			text++
+		} else {
+			numeric++
+		}
+	}
+	return numeric > text
+}
+
+// firstMissing 回傳時間欄與 columns 資料欄中第一個空白或無法解析格子的位置
+func firstMissing(r [][]string, columns []int) (int, int) {
+	numeric := numericTime(r)
+	for i := 1; i < len(r); i++ {
+		if missingCell(r[i], 0, numeric) {
+			return i, 0
+		}
+		for _, j := range columns {
+			if missingCell(r[i], j, true) {
+				return i, j
+			}
+		}
+	}
+	return -1, -1
+}
+
+// dropMissingTime 刪除時間空白或無法解析的列，這些列無法排序也無法補值
+func dropMissingTime(r [][]string) [][]string {
+	numeric := numericTime(r)
+	result := make([][]string, 0, len(r))
+	result = append(result, r[0])
+	for i := 1; i < len(r); i++ {
+		if !missingCell(r[i], 0, numeric) {
+			result = append(result, r[i])
+		}
+	}
+	if len(result) < len(r) {
+		fmt.Printf("已刪除 %d 列時間空白或無法解析的資料\n", len(r)-len(result))
+	}
+	return result
+}
+
+// fillMissing 依 strategy 處理 columns 資料欄中空白或非數字的格子: 1 線性內插, 2 沿用前值, 3 刪除該列
+// 內插與沿用前值依資料列順序進行，呼叫前需先依時間排序
+func fillMissing(r [][]string, columns []int, strategy int) [][]string {
+	if strategy == 3 {
+		result := make([][]string, 0, len(r))
+		result = append(result, r[0])
+		for i := 1; i < len(r); i++ {
+			ok := true
+			for _, j := range columns {
+				if missingCell(r[i], j, true) {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				result = append(result, r[i])
+			}
+		}
+		if len(result) < len(r) {
+			fmt.Printf("已刪除 %d 列\n", len(r)-len(result))
+		}
+		return result
+	}
+	for _, j := range columns {
+		column := make([]float64, 0, len(r))
+		for i := 1; i < len(r); i++ {
+			v, err := strconv.ParseFloat(strings.TrimSpace(r[i][j]), 64)
+			if err != nil {
+				v = math.NaN()
+			}
+			column = append(column, v)
+		}
+		switch strategy {
+		case 1:
+			util.Interpolate(column)
+		case 2:
+			util.Hold(column)
+		}
+		for i := 1; i < len(r); i++ {
+			if missingCell(r[i], j, true) && !math.IsNaN(column[i-1]) {
+				r[i][j] = strconv.FormatFloat(column[i-1], 'g', -1, 64)
+			}
+		}
+	}
+	return r
+}
+
+// samplingRate 由第一欄時間推算取樣頻率，第一欄不是數字時回傳 0
+func samplingRate(r [][]string) (float64, int) {
+	times, ok := timeColumn(r)
+	if !ok {
+		return 0, 0
+	}
+	return util.SamplingRate[float64](times)
+}
+
+// timeColumn 解析第一欄時間，第一欄不是數字時回傳 false
+func timeColumn(r [][]string) ([]float64, bool) {
+	times := make([]float64, 0, len(r))
+	for i := 1; i < len(r); i++ {
+		t, err := strconv.ParseFloat(strings.TrimSpace(r[i][0]), 64)
+		if err != nil {
+			return nil, false
+		}
+		times = append(times, t)
+	}
+	return times, true
+}
+
+// sortByTime 依時間排序資料列，相同時間只保留第一筆
+func sortByTime(r [][]string, times []float64) [][]string {
+	index := make([]int, len(times))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(a, b int) bool {
+		return times[index[a]] < times[index[b]]
+	})
+	result := make([][]string, 0, len(r))
+	result = append(result, r[0])
+	for k, i := range index {
+		if k > 0 && times[i] == times[index[k-1]] {
+			continue
+		}
+		result = append(result, r[i+1])
+	}
+	fmt.Printf("已排序並刪除 %d 筆重複時間\n", len(r)-len(result))
+	return result
+}
+
+// windowSize 將 "100ms" 依取樣頻率換算成筆數，沒有 ms 時直接當作筆數
+func windowSize(s string, rate float64) int {
+	if ms, ok := strings.CutSuffix(s, "ms"); ok {
+		v, err := strconv.ParseFloat(strings.TrimSpace(ms), 64)
+		if err != nil || rate <= 0 {
+			return 0
+		}
+		return int(math.Round(v * rate / 1000))
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(s))
+	return n
+}
+
+// runSelftest 以小量資料檢查計算與 csv 讀寫，供安裝後確認環境
+func runSelftest() error {
+	if n := util.Str2Number[int, int]("3.70188E-05", 10); n != 370188 {
+		return fmt.Errorf("Str2Number: got %d", n)
+	}
+	records := [][]string{{"time", "a"}, {"0", "1E-05"}, {"0.01", "3E-05"}, {"0.02", "8E-05"}, {"0.03", "5E-05"}, {"0.04", "2E-05"}}
+	from, maxMean := maxMeanWindow(records, 1, 3)
+	if from != 2 || fmt.Sprintf("%.10f", maxMean) != "0.0000533333" {
+		return fmt.Errorf("max mean: got row %d value %.10f", from, maxMean)
+	}
+	dir, err := os.MkdirTemp("", "count_mean")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "selftest.csv")
+	want := [][]string{{"time", "a"}, {"0", "3.70188E-05"}, {"0.01", "0.001356"}}
+	if err := writeResult(name, want); err != nil {
+		return err
+	}
+	f, err := os.Open(name)
 	if err != nil {
-		log.Fatalln("failed to open file", err)
+		return err
 	}
+	defer f.Close()
+	got, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return err
+	}
+	got[0][0] = strings.TrimPrefix(got[0][0], "\uFEFF")
+	if len(got) != len(want) {
+		return errors.New("csv round trip: row count differs")
+	}
+	for i := range want {
+		if strings.Join(got[i], ",") != strings.Join(want[i], ",") {
+			return fmt.Errorf("csv round trip: row %d differs", i)
+		}
+	}
+	return nil
+}
+
+func writeResult(name string, result [][]string) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func(file *os.File) {
+		e := file.Close()
+		if e != nil {
+
+		}
+	}(file)
 
 	bom := []byte{0xEF, 0xBB, 0xBF}
-	resultFile.Write(bom)
-	w := csv.NewWriter(resultFile)
+	if _, err = file.Write(bom); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	w := csv.NewWriter(file)
 	err = w.WriteAll(result)
 	if err != nil {
-		log.Fatalln("failed to write result", err)
+		return fmt.Errorf("failed to write result: %w", err)
 	}
+	return nil
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWindowSize(t *testing.T) {
+	t.Run("test 1", func(t *testing.T) {
+		require.Equal(t, 200, windowSize("100 ms", 2000))
+	})
+	t.Run("test 2", func(t *testing.T) {
+		require.Equal(t, 10, windowSize("100ms", 100))
+	})
+	t.Run("test 3", func(t *testing.T) {
+		require.Equal(t, 37, windowSize(" 37 ", 0))
+	})
+	t.Run("test 4", func(t *testing.T) {
+		require.Equal(t, 0, windowSize("100ms", 0))
+	})
+}
+
+func TestFillMissing(t *testing.T) {
+	t.Run("test 1", func(t *testing.T) {
+		r := [][]string{{"time", "a"}, {"0", "1"}, {"", "2"}, {"0.02", ""}, {"0.03", "4"}}
+		r = dropMissingTime(r)
+		r = fillMissing(r, []int{1}, 1)
+		require.Equal(t, [][]string{{"time", "a"}, {"0", "1"}, {"0.02", "2.5"}, {"0.03", "4"}}, r)
+	})
+	t.Run("test 2", func(t *testing.T) {
+		r := [][]string{{"time", "a"}, {"0", "1"}, {"0.01", "x"}, {"0.02", "3"}}
+		r = fillMissing(r, []int{1}, 2)
+		require.Equal(t, [][]string{{"time", "a"}, {"0", "1"}, {"0.01", "1"}, {"0.02", "3"}}, r)
+	})
+	t.Run("test 3", func(t *testing.T) {
+		r := [][]string{{"time", "a"}, {"0", "1"}, {"0.01", ""}, {"0.02", "3"}}
+		r = fillMissing(r, []int{1}, 3)
+		require.Equal(t, [][]string{{"time", "a"}, {"0", "1"}, {"0.02", "3"}}, r)
+	})
+}
+
+func TestSortByTime(t *testing.T) {
+	t.Run("test 1", func(t *testing.T) {
+		r := [][]string{{"time", "a"}, {"0.02", "3"}, {"0", "1"}, {"0.01", "2"}, {"0.01", "9"}}
+		times, ok := timeColumn(r)
+		require.True(t, ok)
+		r = sortByTime(r, times)
+		require.Equal(t, [][]string{{"time", "a"}, {"0", "1"}, {"0.01", "2"}, {"0.02", "3"}}, r)
+	})
+	t.Run("test 2", func(t *testing.T) {
+		_, ok := timeColumn([][]string{{"name", "a"}, {"left", "1"}})
+		require.False(t, ok)
+	})
+}
+
+func TestPhase(t *testing.T) {
+	r := [][]string{{"time", "a"}, {"0", "1"}, {"0.1", "2"}, {"0.2", "3"}, {"0.3", "4"}, {"0.4", "5"}, {"0.5", "6"}}
+	t.Run("test 1", func(t *testing.T) {
+		o := [][]string{{"phase", "time"}, {"p1", "0"}, {"p2", "0.15"}, {"p3", "0.25"}, {"p4", "0.35"}, {"p5", "0.5"}}
+		require.True(t, checkPhasePoints(o, r))
+		require.Equal(t, -1, emptyPhase(o, r))
+	})
+	t.Run("test 2", func(t *testing.T) {
+		o := [][]string{{"phase", "time"}, {"p1", "0"}, {"p2", "0.15"}, {"p3", "0.18"}, {"p4", "0.35"}, {"p5", "0.5"}}
+		require.True(t, checkPhasePoints(o, r))
+		require.Equal(t, 1, emptyPhase(o, r))
+	})
+	t.Run("test 3", func(t *testing.T) {
+		o := [][]string{{"phase", "time"}, {"p1", "0"}, {"p2", "0.25"}, {"p3", "0.15"}, {"p4", "0.35"}, {"p5", "0.5"}}
+		require.False(t, checkPhasePoints(o, r))
+	})
+	t.Run("test 4", func(t *testing.T) {
+		o := [][]string{{"phase"}, {"p1"}, {"p2"}, {"p3"}, {"p4"}, {"p5"}}
+		require.False(t, checkPhasePoints(o, r))
+	})
+	t.Run("test 5", func(t *testing.T) {
+		o := [][]string{{"phase", "time"}, {"p1", "0"}, {"p2", "0.15"}}
+		require.False(t, checkPhasePoints(o, r))
+	})
+}
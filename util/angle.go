@@ -0,0 +1,11 @@
+package util
+
+import "math"
+
+// JointAngle2D 回傳 a-b 與 c-b 兩線段在 b 點的夾角(度)
+func JointAngle2D(ax, ay, bx, by, cx, cy float64) float64 {
+	ux, uy := ax-bx, ay-by
+	vx, vy := cx-bx, cy-by
+	rad := math.Atan2(math.Abs(ux*vy-uy*vx), ux*vx+uy*vy)
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestJointAngle2D(t *testing.T) {
+	t.Run("test 1", func(t *testing.T) {
+		r := JointAngle2D(0, 1, 0, 0, 1, 0)
+		require.InDelta(t, 90, r, 1e-9)
+	})
+	t.Run("test 2", func(t *testing.T) {
+		r := JointAngle2D(0, 2, 0, 1, 0, 0)
+		require.InDelta(t, 180, r, 1e-9)
+	})
+	t.Run("test 3", func(t *testing.T) {
+		r := JointAngle2D(1, 1, 0, 0, 1, 0)
+		require.InDelta(t, 45, r, 1e-9)
+	})
+}
@@ -0,0 +1,50 @@
+package util
+
+import "math"
+
+// Interpolate 以線性內插補上 NaN，頭尾的 NaN 沿用最近的有效值
+func Interpolate(a []float64) {
+	prev := -1
+	for i, value := range a {
+		if math.IsNaN(value) {
+			continue
+		}
+		switch {
+		case prev == -1:
+			for j := 0; j < i; j++ {
+				a[j] = value
+			}
+		case i-prev > 1:
+			step := (value - a[prev]) / float64(i-prev)
+			for j := prev + 1; j < i; j++ {
+				a[j] = a[prev] + step*float64(j-prev)
+			}
+		}
+		prev = i
+	}
+	if prev == -1 {
+		return
+	}
+	for j := prev + 1; j < len(a); j++ {
+		a[j] = a[prev]
+	}
+}
+
+// Hold 以前一個有效值補上 NaN，開頭的 NaN 沿用第一個有效值
+func Hold(a []float64) {
+	prev := -1
+	for i, value := range a {
+		if math.IsNaN(value) {
+			if prev != -1 {
+				a[i] = a[prev]
+			}
+			continue
+		}
+		if prev == -1 {
+			for j := 0; j < i; j++ {
+				a[j] = value
+			}
+		}
+		prev = i
+	}
+}
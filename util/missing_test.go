@@ -0,0 +1,35 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	nan := math.NaN()
+	t.Run("test 1", func(t *testing.T) {
+		a := []float64{1, nan, nan, 4}
+		Interpolate(a)
+		require.Equal(t, []float64{1, 2, 3, 4}, a)
+	})
+	t.Run("test 2", func(t *testing.T) {
+		a := []float64{nan, 2, nan, 6, nan}
+		Interpolate(a)
+		require.Equal(t, []float64{2, 2, 4, 6, 6}, a)
+	})
+}
+
+func TestHold(t *testing.T) {
+	nan := math.NaN()
+	t.Run("test 1", func(t *testing.T) {
+		a := []float64{1, nan, nan, 4}
+		Hold(a)
+		require.Equal(t, []float64{1, 1, 1, 4}, a)
+	})
+	t.Run("test 2", func(t *testing.T) {
+		a := []float64{nan, 2, nan, 6, nan}
+		Hold(a)
+		require.Equal(t, []float64{2, 2, 2, 6, 6}, a)
+	})
+}
@@ -0,0 +1,15 @@
+package util
+
+// TimeDisorder 回傳時間序列中倒退與重複的次數
+func TimeDisorder[T Number](t []T) (int, int) {
+	backwards, duplicated := 0, 0
+	for i := 1; i < len(t); i++ {
+		switch {
+		case t[i] < t[i-1]:
+			backwards++
+		case t[i] == t[i-1]:
+			duplicated++
+		}
+	}
+	return backwards, duplicated
+}
@@ -0,0 +1,19 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTimeDisorder(t *testing.T) {
+	t.Run("test 1", func(t *testing.T) {
+		backwards, duplicated := TimeDisorder[float64]([]float64{0, 0.01, 0.02, 0.03})
+		require.Equal(t, 0, backwards)
+		require.Equal(t, 0, duplicated)
+	})
+	t.Run("test 2", func(t *testing.T) {
+		backwards, duplicated := TimeDisorder[float64]([]float64{0, 0.02, 0.01, 0.01, 0.03})
+		require.Equal(t, 1, backwards)
+		require.Equal(t, 1, duplicated)
+	})
+}
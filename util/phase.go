@@ -0,0 +1,11 @@
+package util
+
+// UnorderedIndex 回傳第一個比前一個小的位置，全部依序排列時回傳 -1
+func UnorderedIndex[T Number](a []T) int {
+	for i := 1; i < len(a); i++ {
+		if a[i] < a[i-1] {
+			return i
+		}
+	}
+	return -1
+}
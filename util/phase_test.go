@@ -0,0 +1,21 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestUnorderedIndex(t *testing.T) {
+	t.Run("test 1", func(t *testing.T) {
+		r := UnorderedIndex[float64]([]float64{11.097, 11.529, 11.56275, 11.8665, 12.1635})
+		require.Equal(t, -1, r)
+	})
+	t.Run("test 2", func(t *testing.T) {
+		r := UnorderedIndex[float64]([]float64{11.097, 11.529, 11.52, 11.8665})
+		require.Equal(t, 2, r)
+	})
+	t.Run("test 3", func(t *testing.T) {
+		r := UnorderedIndex[float64]([]float64{1, 1, 2})
+		require.Equal(t, -1, r)
+	})
+}
@@ -0,0 +1,28 @@
+package util
+
+import "sort"
+
+// SamplingRate 由時間序列推算取樣頻率(Hz)，並回傳間隔異常(重複、倒退或跳點)的數量
+func SamplingRate[T Number](t []T) (float64, int) {
+	if len(t) < 2 {
+		return 0, 0
+	}
+	diffs := make([]float64, 0, len(t)-1)
+	for i := 1; i < len(t); i++ {
+		diffs = append(diffs, float64(t[i]-t[i-1]))
+	}
+	sorted := make([]float64, len(diffs))
+	copy(sorted, diffs)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+	if median <= 0 {
+		return 0, len(diffs)
+	}
+	irregular := 0
+	for _, d := range diffs {
+		if d <= 0 || d > median*1.5 {
+			irregular++
+		}
+	}
+	return 1 / median, irregular
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSamplingRate(t *testing.T) {
+	t.Run("test 1", func(t *testing.T) {
+		rate, irregular := SamplingRate[float64]([]float64{0, 0.0005, 0.001, 0.0015, 0.002})
+		require.InDelta(t, 2000, rate, 1e-6)
+		require.Equal(t, 0, irregular)
+	})
+	t.Run("test 2", func(t *testing.T) {
+		rate, irregular := SamplingRate[float64]([]float64{0, 0.01, 0.02, 0.02, 0.05, 0.06})
+		require.InDelta(t, 100, rate, 1e-6)
+		require.Equal(t, 2, irregular)
+	})
+	t.Run("test 3", func(t *testing.T) {
+		rate, irregular := SamplingRate[float64]([]float64{1})
+		require.Equal(t, float64(0), rate)
+		require.Equal(t, 0, irregular)
+	})
+}
@@ -0,0 +1,50 @@
+package util
+
+// SplitByGap 在相鄰時間差大於 gap 處切開，回傳每段的 [開始, 結束) 位置
+func SplitByGap[T Number](t []T, gap T) [][2]int {
+	if len(t) == 0 {
+		return nil
+	}
+	segments := make([][2]int, 0, 4)
+	start := 0
+	for i := 1; i < len(t); i++ {
+		if t[i]-t[i-1] > gap {
+			segments = append(segments, [2]int{start, i})
+			start = i
+		}
+	}
+	return append(segments, [2]int{start, len(t)})
+}
+
+// SplitByThreshold 回傳數值連續 >= threshold 的每段 [開始, 結束) 位置
+func SplitByThreshold[T Number](a []T, threshold T) [][2]int {
+	segments := make([][2]int, 0, 4)
+	start := -1
+	for i, value := range a {
+		switch {
+		case value >= threshold && start == -1:
+			start = i
+		case value < threshold && start != -1:
+			segments = append(segments, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start != -1 {
+		segments = append(segments, [2]int{start, len(a)})
+	}
+	return segments
+}
+
+// SplitByOnset 以每次數值升到 >= threshold 的位置為起點，每段到下一次起點為止
+func SplitByOnset[T Number](a []T, threshold T) [][2]int {
+	gates := SplitByThreshold(a, threshold)
+	segments := make([][2]int, 0, len(gates))
+	for k, gate := range gates {
+		end := len(a)
+		if k+1 < len(gates) {
+			end = gates[k+1][0]
+		}
+		segments = append(segments, [2]int{gate[0], end})
+	}
+	return segments
+}
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSplitByGap(t *testing.T) {
+	t.Run("test 1", func(t *testing.T) {
+		r := SplitByGap[float64]([]float64{0, 0.01, 0.02, 1, 1.01, 3}, 0.5)
+		require.Equal(t, [][2]int{{0, 3}, {3, 5}, {5, 6}}, r)
+	})
+	t.Run("test 2", func(t *testing.T) {
+		r := SplitByGap[float64]([]float64{0, 0.01, 0.02}, 0.5)
+		require.Equal(t, [][2]int{{0, 3}}, r)
+	})
+}
+
+func TestSplitByThreshold(t *testing.T) {
+	t.Run("test 1", func(t *testing.T) {
+		r := SplitByThreshold[float64]([]float64{0, 5, 5, 0, 0, 5, 5}, 2.5)
+		require.Equal(t, [][2]int{{1, 3}, {5, 7}}, r)
+	})
+	t.Run("test 2", func(t *testing.T) {
+		r := SplitByThreshold[float64]([]float64{0, 0, 0}, 2.5)
+		require.Equal(t, [][2]int{}, r)
+	})
+}
+
+func TestSplitByOnset(t *testing.T) {
+	t.Run("test 1", func(t *testing.T) {
+		r := SplitByOnset[float64]([]float64{0, 5, 0, 0, 0, 5, 0, 0}, 2.5)
+		require.Equal(t, [][2]int{{1, 5}, {5, 8}}, r)
+	})
+	t.Run("test 2", func(t *testing.T) {
+		r := SplitByOnset[float64]([]float64{0, 0, 0}, 2.5)
+		require.Equal(t, [][2]int{}, r)
+	})
+}
@@ -7,19 +7,21 @@ import (
 )
 
 func Str2Number[T Number, U ~int](s string, move U) T {
-	a := strings.Split(s, "E")
+	a, e, hasE := strings.Cut(s, "E")
 	// 去除空白
-	b := strings.Replace(a[0], " ", "", -1)
-	f, err := strconv.ParseFloat(b, 64)
-	if len(a) == 1 {
-		n2 := math.Pow10(int(move))
-		return T(f * n2)
+	if strings.IndexByte(a, ' ') >= 0 {
+		a = strings.ReplaceAll(a, " ", "")
 	}
-	n, err := strconv.ParseInt(a[1], 10, 64)
+	f, _ := strconv.ParseFloat(a, 64)
+	if !hasE {
+		return T(f * math.Pow10(int(move)))
+	}
+	if i := strings.IndexByte(e, 'E'); i >= 0 {
+		e = e[:i]
+	}
+	n, err := strconv.ParseInt(e, 10, 64)
 	if err != nil {
 		panic(err)
 	}
-	n2 := math.Pow10(int(int64(move) + n))
-	r := f * n2
-	return T(r)
+	return T(f * math.Pow10(int(int64(move)+n)))
 }
@@ -14,4 +14,20 @@ func TestStr2int(t *testing.T) {
 		r := Str2Number[int, int]("0.001356", 6)
 		require.Equal(t, 1356, r)
 	})
+	t.Run("test 3", func(t *testing.T) {
+		r := Str2Number[float64, int](" 1.5 E02", 0)
+		require.Equal(t, float64(150), r)
+	})
+	t.Run("test 4", func(t *testing.T) {
+		require.Panics(t, func() {
+			Str2Number[float64, int]("1.5E", 0)
+		})
+	})
+}
+
+func BenchmarkStr2Number(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Str2Number[float64, int]("3.70188E-05", 10)
+		Str2Number[float64, int]("0.000106518", 10)
+	}
 }
@@ -0,0 +1,12 @@
+package util
+
+import "math"
+
+// SymmetryIndex 回傳 (左-右)/((左+右)/2)*100，左+右為 0 時無法定義，回傳 NaN
+func SymmetryIndex[T Number](left, right T) float64 {
+	l, r := float64(left), float64(right)
+	if l+r == 0 {
+		return math.NaN()
+	}
+	return (l - r) / ((l + r) / 2) * 100
+}
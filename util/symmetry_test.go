@@ -0,0 +1,26 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestSymmetryIndex(t *testing.T) {
+	t.Run("test 1", func(t *testing.T) {
+		r := SymmetryIndex[float64](3, 1)
+		require.Equal(t, float64(100), r)
+	})
+	t.Run("test 2", func(t *testing.T) {
+		r := SymmetryIndex[int](2, 2)
+		require.Equal(t, float64(0), r)
+	})
+	t.Run("test 3", func(t *testing.T) {
+		r := SymmetryIndex[float64](0, 0)
+		require.True(t, math.IsNaN(r))
+	})
+	t.Run("test 4", func(t *testing.T) {
+		r := SymmetryIndex[float64](-3, 3)
+		require.True(t, math.IsNaN(r))
+	})
+}